@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims identifies the caller an authenticated request was made on behalf
+// of. UserID and ClientID together form the object key prefix the caller is
+// confined to.
+type Claims struct {
+	UserID   string `json:"userid"`
+	ClientID string `json:"clientid"`
+}
+
+// Prefix returns the object key namespace this caller is restricted to.
+func (c Claims) Prefix() string {
+	return fmt.Sprintf("%s/%s/", c.UserID, c.ClientID)
+}
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator extracts Claims from an inbound request. Implementations are
+// swapped via AUTH_MODE so operators can move between static-header, JWT, and
+// (future) OIDC verification without touching any handler code.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Claims, error)
+}
+
+// HeaderAuthenticator trusts an X-User-Claim header containing a JSON object
+// with "userid" and "clientid", as set by an upstream forward-auth proxy.
+type HeaderAuthenticator struct{}
+
+func (HeaderAuthenticator) Authenticate(r *http.Request) (Claims, error) {
+	raw := r.Header.Get("X-User-Claim")
+	if raw == "" {
+		return Claims{}, errUnauthenticated
+	}
+
+	var c Claims
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return Claims{}, fmt.Errorf("invalid X-User-Claim header: %w", err)
+	}
+	if c.UserID == "" || c.ClientID == "" {
+		return Claims{}, errUnauthenticated
+	}
+	return c, nil
+}
+
+// JWTAuthenticator verifies a Bearer JWT signed with JWT_SECRET and reads the
+// userid/clientid claims from it.
+type JWTAuthenticator struct {
+	Secret []byte
+}
+
+func (a JWTAuthenticator) Authenticate(r *http.Request) (Claims, error) {
+	authHeader := r.Header.Get("Authorization")
+	tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || tokenString == "" {
+		return Claims{}, errUnauthenticated
+	}
+
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return a.Secret, nil
+	})
+	if err != nil {
+		return Claims{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, errUnauthenticated
+	}
+
+	c := Claims{
+		UserID:   fmt.Sprintf("%v", claims["userid"]),
+		ClientID: fmt.Sprintf("%v", claims["clientid"]),
+	}
+	if c.UserID == "" || c.ClientID == "" || claims["userid"] == nil || claims["clientid"] == nil {
+		return Claims{}, errUnauthenticated
+	}
+	return c, nil
+}
+
+// authenticator is the process-wide Authenticator, chosen at startup via
+// AUTH_MODE (header|jwt). Defaults to header-based auth.
+var authenticator Authenticator = HeaderAuthenticator{}
+
+func newAuthenticator() Authenticator {
+	switch strings.ToLower(os.Getenv("AUTH_MODE")) {
+	case "jwt":
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			log.Fatal("AUTH_MODE=jwt requires JWT_SECRET to be set")
+		}
+		return JWTAuthenticator{Secret: []byte(secret)}
+	default:
+		return HeaderAuthenticator{}
+	}
+}
+
+type claimsContextKey struct{}
+
+// withAuth authenticates the request and injects the resulting Claims into
+// its context before delegating to next. Requests that fail authentication
+// are rejected with 401 and never reach the wrapped handler.
+func withAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticator.Authenticate(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Unauthorized: %v", err), http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), claimsContextKey{}, claims)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// claimsFromContext returns the Claims attached by withAuth. It panics if
+// called outside a withAuth-wrapped handler, since that indicates a
+// programming error rather than a request-time failure.
+func claimsFromContext(ctx context.Context) Claims {
+	claims, ok := ctx.Value(claimsContextKey{}).(Claims)
+	if !ok {
+		panic("claimsFromContext: no claims in context, handler not wrapped with withAuth")
+	}
+	return claims
+}
+
+// scopedKey namespaces an object key under the caller's prefix and rejects
+// any caller-supplied key that tries to escape it (e.g. via "..").
+func scopedKey(claims Claims, key string) (string, error) {
+	if strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return claims.Prefix() + key, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// downloadHandler serves GET /download (streams the object, honoring Range
+// and conditional-request headers) and HEAD /download (StatObject only, no
+// body) for the caller-scoped key in the "key" query parameter.
+func downloadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	claims := claimsFromContext(r.Context())
+
+	objectName := r.URL.Query().Get("key")
+	if objectName == "" {
+		http.Error(w, "Missing 'key' query parameter", http.StatusBadRequest)
+		return
+	}
+	key, err := scopedKey(claims, objectName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	sse, err := resolveSSEC(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sse == nil && strings.EqualFold(r.URL.Query().Get("sse"), "c") {
+		if sseCfg.defaultSSEC == nil {
+			http.Error(w, "sse=c requested but no default SSE-C key is configured", http.StatusBadRequest)
+			return
+		}
+		sse = sseCfg.defaultSSEC
+	}
+	opts := minio.GetObjectOptions{ServerSideEncryption: sse}
+	if err := applyConditionalHeaders(r, &opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		headObject(ctx, w, key, opts)
+		return
+	}
+
+	getObject(ctx, w, key, opts)
+}
+
+// applyConditionalHeaders translates If-None-Match, If-Modified-Since, and
+// Range request headers into the matching minio.GetObjectOptions setters.
+func applyConditionalHeaders(r *http.Request, opts *minio.GetObjectOptions) error {
+	if etag := r.Header.Get("If-None-Match"); etag != "" {
+		if err := opts.SetMatchETagExcept(strings.Trim(etag, `"`)); err != nil {
+			return fmt.Errorf("invalid If-None-Match: %w", err)
+		}
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return fmt.Errorf("invalid If-Modified-Since: %w", err)
+		}
+		if err := opts.SetModified(t); err != nil {
+			return fmt.Errorf("invalid If-Modified-Since: %w", err)
+		}
+	}
+	if rng := r.Header.Get("Range"); rng != "" {
+		start, end, err := parseRange(rng)
+		if err != nil {
+			return fmt.Errorf("invalid Range: %w", err)
+		}
+		// "bytes=0-" is the whole object; SetRange(0, 0) would mean the
+		// first byte only, so no range is set for it.
+		if start == 0 && end == 0 && strings.HasSuffix(rng, "-") {
+			return nil
+		}
+		if err := opts.SetRange(start, end); err != nil {
+			return fmt.Errorf("invalid Range: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseRange parses a single-range "bytes=start-end" header, where start or
+// end may be omitted (e.g. "bytes=500-" or "bytes=-500"), matching the forms
+// minio.GetObjectOptions.SetRange accepts (start==0 with a negative end
+// means "last |end| bytes").
+func parseRange(header string) (start, end int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported range unit in %q", header)
+	}
+	if strings.Contains(spec, ",") {
+		return 0, 0, fmt.Errorf("multiple ranges not supported")
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("malformed range %q", header)
+	}
+
+	if parts[0] == "" {
+		suffix, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, -suffix, nil
+	}
+
+	start, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if parts[1] == "" {
+		return start, 0, nil
+	}
+	end, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func getObject(ctx context.Context, w http.ResponseWriter, key string, opts minio.GetObjectOptions) {
+	object, err := minioClient.GetObject(ctx, bucketName, key, opts)
+	if err != nil {
+		writeObjectError(w, err)
+		return
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		writeObjectError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", info.ETag)
+	w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+
+	status := http.StatusOK
+	if cr := info.Metadata.Get("Content-Range"); cr != "" {
+		w.Header().Set("Content-Range", cr)
+		status = http.StatusPartialContent
+	}
+
+	w.WriteHeader(status)
+	io.Copy(w, object)
+}
+
+func headObject(ctx context.Context, w http.ResponseWriter, key string, opts minio.GetObjectOptions) {
+	info, err := minioClient.StatObject(ctx, bucketName, key, minio.StatObjectOptions{ServerSideEncryption: opts.ServerSideEncryption})
+	if err != nil {
+		writeObjectError(w, err)
+		return
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", info.ETag)
+	w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeObjectError(w http.ResponseWriter, err error) {
+	errResp := minio.ToErrorResponse(err)
+	switch errResp.Code {
+	case "NotModified":
+		w.WriteHeader(http.StatusNotModified)
+	case "NoSuchKey":
+		http.Error(w, "Not found", http.StatusNotFound)
+	default:
+		http.Error(w, fmt.Sprintf("Download failed: %v", err), http.StatusInternalServerError)
+	}
+}
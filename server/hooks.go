@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Hook is a webhook registration: the operator's URL receives every
+// bucket notification whose key matches Prefix/Suffix and whose event type
+// is in Events, signed with Secret. Owner is the claims prefix of whoever
+// registered the hook and is never returned to API callers.
+type Hook struct {
+	ID     string   `json:"id"`
+	Owner  string   `json:"-"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Prefix string   `json:"prefix"`
+	Suffix string   `json:"suffix"`
+	Secret string   `json:"secret"`
+}
+
+// effectivePrefix is the caller-supplied Prefix forced under the owning
+// tenant's namespace, so a hook can never observe another tenant's objects
+// regardless of what Prefix it was registered with.
+func (h Hook) effectivePrefix() string {
+	return h.Owner + h.Prefix
+}
+
+// Matches reports whether a notification for the given object key and
+// event name should be delivered to this hook.
+func (h Hook) Matches(key, event string) bool {
+	if !strings.HasPrefix(key, h.effectivePrefix()) {
+		return false
+	}
+	if h.Suffix != "" && !strings.HasSuffix(key, h.Suffix) {
+		return false
+	}
+	for _, e := range h.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+var hooksBucketName = []byte("hooks")
+
+// hookStore persists registered hooks in BoltDB so they survive restarts.
+type hookStore struct {
+	db *bolt.DB
+}
+
+func newHookStore() (*hookStore, error) {
+	path := os.Getenv("HOOKS_DB_PATH")
+	if path == "" {
+		path = "hooks.db"
+	}
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("opening hooks db: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(hooksBucketName)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initializing hooks bucket: %w", err)
+	}
+	return &hookStore{db: db}, nil
+}
+
+// List returns every hook in the store, regardless of owner. It backs the
+// webhook dispatcher, which must consider all tenants' hooks to find
+// matches; API callers should use ListByOwner instead.
+func (s *hookStore) List() ([]Hook, error) {
+	var hooks []Hook
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucketName).ForEach(func(k, v []byte) error {
+			var h Hook
+			if err := json.Unmarshal(v, &h); err != nil {
+				return err
+			}
+			hooks = append(hooks, h)
+			return nil
+		})
+	})
+	return hooks, err
+}
+
+// ListByOwner returns only the hooks registered by the given owner.
+func (s *hookStore) ListByOwner(owner string) ([]Hook, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]Hook, 0, len(all))
+	for _, h := range all {
+		if h.Owner == owner {
+			owned = append(owned, h)
+		}
+	}
+	return owned, nil
+}
+
+// Get returns a single hook by ID, or an error if it doesn't exist.
+func (s *hookStore) Get(id string) (Hook, error) {
+	var h Hook
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(hooksBucketName).Get([]byte(id))
+		if v == nil {
+			return fmt.Errorf("hook %q not found", id)
+		}
+		return json.Unmarshal(v, &h)
+	})
+	return h, err
+}
+
+func (s *hookStore) Create(h Hook) (Hook, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Hook{}, fmt.Errorf("generating hook id: %w", err)
+	}
+	h.ID = hex.EncodeToString(id)
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		return Hook{}, err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucketName).Put([]byte(h.ID), data)
+	})
+	if err != nil {
+		return Hook{}, fmt.Errorf("persisting hook: %w", err)
+	}
+	return h, nil
+}
+
+// Delete removes the hook with the given ID, but only if it is owned by
+// owner, so one tenant can never remove another tenant's hook.
+func (s *hookStore) Delete(owner, id string) error {
+	h, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if h.Owner != owner {
+		return fmt.Errorf("hook %q not found", id)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucketName).Delete([]byte(id))
+	})
+}
+
+var hooks *hookStore
+
+// hooksHandler implements the /hooks CRUD API: POST registers a new hook,
+// GET lists the caller's hooks, DELETE removes one by "id".
+func hooksHandler(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodPost:
+		var h Hook
+		if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid hook body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if h.URL == "" || len(h.Events) == 0 {
+			http.Error(w, "'url' and 'events' are required", http.StatusBadRequest)
+			return
+		}
+		h.Owner = claims.Prefix()
+		created, err := hooks.Create(h)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(created)
+
+	case http.MethodGet:
+		list, err := hooks.ListByOwner(claims.Prefix())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			http.Error(w, "Missing 'id' query parameter", http.StatusBadRequest)
+			return
+		}
+		if err := hooks.Delete(claims.Prefix(), id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// defaultMaxKeys caps a listing page when the caller doesn't specify
+// max-keys, mirroring S3's own default.
+const defaultMaxKeys = 1000
+
+type listedObject struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified"`
+	ETag         string `json:"etag"`
+	StorageClass string `json:"storage_class"`
+	VersionID    string `json:"version_id,omitempty"`
+}
+
+type listResponse struct {
+	Objects               []listedObject `json:"objects"`
+	CommonPrefixes        []string       `json:"common_prefixes"`
+	NextContinuationToken string         `json:"next_continuation_token,omitempty"`
+	IsTruncated           bool           `json:"is_truncated"`
+}
+
+// listHandler returns a JSON, paginated view of the caller's objects. It
+// accepts "prefix", "delimiter" (only "/" is supported, matching what the
+// underlying SDK hardcodes for non-recursive listing), "max-keys", and
+// "continuation-token" query parameters, and an optional "versions=true" to
+// list all object versions in a versioned bucket.
+func listHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	claims := claimsFromContext(r.Context())
+	q := r.URL.Query()
+
+	maxKeys := defaultMaxKeys
+	if v := q.Get("max-keys"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxKeys = n
+		}
+	}
+
+	delimiter := q.Get("delimiter")
+	if delimiter != "" && delimiter != "/" {
+		http.Error(w, "Only \"/\" is supported as a delimiter", http.StatusBadRequest)
+		return
+	}
+
+	opts := minio.ListObjectsOptions{
+		Prefix:       claims.Prefix() + q.Get("prefix"),
+		Recursive:    delimiter == "",
+		MaxKeys:      maxKeys,
+		StartAfter:   q.Get("continuation-token"),
+		WithVersions: q.Get("versions") == "true",
+	}
+
+	resp := listResponse{
+		Objects:        []listedObject{},
+		CommonPrefixes: []string{},
+	}
+
+	seenPrefixes := make(map[string]bool)
+	count := 0
+	for obj := range minioClient.ListObjects(ctx, bucketName, opts) {
+		if obj.Err != nil {
+			http.Error(w, obj.Err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if count == maxKeys {
+			resp.IsTruncated = true
+			break
+		}
+
+		key := strings.TrimPrefix(obj.Key, claims.Prefix())
+		if delimiter != "" && strings.HasSuffix(obj.Key, delimiter) && obj.Size == 0 {
+			if !seenPrefixes[key] {
+				seenPrefixes[key] = true
+				resp.CommonPrefixes = append(resp.CommonPrefixes, key)
+				count++
+			}
+			continue
+		}
+
+		resp.Objects = append(resp.Objects, listedObject{
+			Key:          key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified.UTC().Format(time.RFC3339),
+			ETag:         obj.ETag,
+			StorageClass: obj.StorageClass,
+			VersionID:    obj.VersionID,
+		})
+		resp.NextContinuationToken = obj.Key
+		count++
+	}
+
+	if !resp.IsTruncated {
+		resp.NextContinuationToken = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
@@ -73,59 +71,23 @@ func main() {
 		}
 	}
 
-	http.HandleFunc("/upload", uploadHandler)
-	http.HandleFunc("/download", downloadHandler)
-	http.HandleFunc("/list", listHandler)
+	authenticator = newAuthenticator()
+	sseCfg = loadSSESettings()
 
-	log.Println("Server running on :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
-}
-
-func uploadHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
-	objectName := r.URL.Query().Get("key")
-	if objectName == "" {
-		http.Error(w, "Missing 'key' query parameter", http.StatusBadRequest)
-		return
-	}
-
-	_, err := minioClient.PutObject(ctx, bucketName, objectName, r.Body, -1, minio.PutObjectOptions{})
+	hooks, err = newHookStore()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Upload failed: %v", err), http.StatusInternalServerError)
-		return
+		log.Fatalf("Failed to open hook store: %v", err)
 	}
+	startWebhookDispatcher(ctx)
 
-	fmt.Fprintf(w, "Uploaded %s successfully\n", objectName)
-}
-
-func downloadHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
-	objectName := r.URL.Query().Get("key")
-	if objectName == "" {
-		http.Error(w, "Missing 'key' query parameter", http.StatusBadRequest)
-		return
-	}
+	http.HandleFunc("/upload", withAuth(uploadHandler))
+	http.HandleFunc("/download", withAuth(downloadHandler)) // handles both GET and HEAD
+	http.HandleFunc("/list", withAuth(listHandler))
+	http.HandleFunc("/presign/get", withAuth(presignGetHandler))
+	http.HandleFunc("/presign/put", withAuth(presignPutHandler))
+	http.HandleFunc("/hooks", withAuth(hooksHandler))
 
-	object, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Download failed: %v", err), http.StatusInternalServerError)
-		return
-	}
-	defer object.Close()
-
-	w.Header().Set("Content-Disposition", "inline")
-	w.WriteHeader(http.StatusOK)
-	io.Copy(w, object)
-}
-
-func listHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
-	for obj := range minioClient.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Recursive: true}) {
-		if obj.Err != nil {
-			http.Error(w, obj.Err.Error(), http.StatusInternalServerError)
-			return
-		}
-		fmt.Fprintln(w, obj.Key)
-	}
+	log.Println("Server running on :8080")
+	log.Fatal(http.ListenAndServe(":8080", nil))
 }
 
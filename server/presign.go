@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// presignResponse is returned by both presign endpoints so clients can
+// perform the transfer directly against MinIO without proxying through us.
+type presignResponse struct {
+	URL       string            `json:"url"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+}
+
+// parsePresignRequest extracts the common "key" and "expires" query
+// parameters shared by the presign endpoints, scoping the key to the
+// caller's namespace.
+func parsePresignRequest(r *http.Request) (key string, expires time.Duration, err error) {
+	claims := claimsFromContext(r.Context())
+
+	objectName := r.URL.Query().Get("key")
+	if objectName == "" {
+		return "", 0, fmt.Errorf("missing 'key' query parameter")
+	}
+	key, err = scopedKey(claims, objectName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	expires = 15 * time.Minute
+	if raw := r.URL.Query().Get("expires"); raw != "" {
+		expires, err = time.ParseDuration(raw)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid 'expires' duration: %w", err)
+		}
+	}
+	return key, expires, nil
+}
+
+// presignGetHandler wraps minioClient.PresignedGetObject, forwarding
+// response-content-disposition and response-content-type overrides so
+// clients can control how the browser handles the downloaded file.
+func presignGetHandler(w http.ResponseWriter, r *http.Request) {
+	key, expires, err := parsePresignRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqParams := url.Values{}
+	if v := r.URL.Query().Get("response-content-disposition"); v != "" {
+		reqParams.Set("response-content-disposition", v)
+	}
+	if v := r.URL.Query().Get("response-content-type"); v != "" {
+		reqParams.Set("response-content-type", v)
+	}
+
+	signedURL, err := minioClient.PresignedGetObject(r.Context(), bucketName, key, expires, reqParams)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Presign failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writePresignResponse(w, signedURL.String(), expires, http.MethodGet, nil)
+}
+
+// presignPutHandler wraps minioClient.PresignedPutObject so clients can
+// upload large objects directly to MinIO without passing through /upload.
+func presignPutHandler(w http.ResponseWriter, r *http.Request) {
+	key, expires, err := parsePresignRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	signedURL, err := minioClient.PresignedPutObject(r.Context(), bucketName, key, expires)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Presign failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writePresignResponse(w, signedURL.String(), expires, http.MethodPut, nil)
+}
+
+func writePresignResponse(w http.ResponseWriter, rawURL string, expires time.Duration, method string, headers map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presignResponse{
+		URL:       rawURL,
+		ExpiresAt: time.Now().Add(expires),
+		Method:    method,
+		Headers:   headers,
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// sseSettings holds the operator-configured server-side encryption policy,
+// loaded once at startup.
+type sseSettings struct {
+	// require rejects any upload that does not resolve to a
+	// ServerSideEncryption, via REQUIRE_SSE.
+	require bool
+	// defaultSSEC is used for `sse=c` requests that don't carry their own
+	// customer key, loaded from the file referenced by SSE_C_KEY_FILE.
+	defaultSSEC encrypt.ServerSide
+}
+
+var sseCfg sseSettings
+
+func loadSSESettings() sseSettings {
+	cfg := sseSettings{
+		require: os.Getenv("REQUIRE_SSE") == "true",
+	}
+
+	if path := os.Getenv("SSE_C_KEY_FILE"); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read SSE_C_KEY_FILE %s: %v", path, err)
+		}
+		sse, err := encrypt.NewSSEC(key)
+		if err != nil {
+			log.Fatalf("Invalid default SSE-C key in %s: %v", path, err)
+		}
+		cfg.defaultSSEC = sse
+	}
+	return cfg
+}
+
+// resolveServerSideEncryption inspects X-Amz-Server-Side-Encryption* headers
+// or the `sse` query parameter and returns the corresponding
+// encrypt.ServerSide for use on PutObjectOptions. It returns a nil
+// ServerSide and nil error when the caller requested no encryption.
+func resolveServerSideEncryption(r *http.Request) (encrypt.ServerSide, error) {
+	if sse, err := resolveSSEC(r); sse != nil || err != nil {
+		return sse, err
+	}
+
+	switch strings.ToLower(r.Header.Get("X-Amz-Server-Side-Encryption")) {
+	case "aes256":
+		return encrypt.NewSSE(), nil
+	case "aws:kms":
+		keyID := r.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id")
+		return encrypt.NewSSEKMS(keyID, nil)
+	}
+
+	switch strings.ToLower(r.URL.Query().Get("sse")) {
+	case "s3":
+		return encrypt.NewSSE(), nil
+	case "kms":
+		return encrypt.NewSSEKMS(r.URL.Query().Get("sse-kms-key-id"), nil)
+	case "c":
+		if sseCfg.defaultSSEC == nil {
+			return nil, fmt.Errorf("sse=c requested but no default SSE-C key is configured")
+		}
+		return sseCfg.defaultSSEC, nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown sse mode %q", r.URL.Query().Get("sse"))
+	}
+}
+
+// resolveSSEC returns the SSE-C ServerSide carried in
+// X-Amz-Server-Side-Encryption-Customer-* headers, if any. It is shared by
+// upload (to set ServerSideEncryption) and download (the matching customer
+// key must be presented again to read the object back).
+func resolveSSEC(r *http.Request) (encrypt.ServerSide, error) {
+	alg := r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm")
+	if alg == "" {
+		return nil, nil
+	}
+	if !strings.EqualFold(alg, "AES256") {
+		return nil, fmt.Errorf("unsupported SSE-C algorithm %q", alg)
+	}
+
+	keyB64 := r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key")
+	key, err := base64.StdEncoding.DecodeString(keyB64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSE-C customer key: %w", err)
+	}
+	return encrypt.NewSSEC(key)
+}
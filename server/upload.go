@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultMaxUploadSize is used when MAX_UPLOAD_SIZE is unset or invalid.
+const defaultMaxUploadSize = 32 << 20 // 32MB
+
+// presignExpiry is how long the signed download URL returned alongside each
+// uploaded file remains valid.
+const presignExpiry = 15 * time.Minute
+
+func maxUploadSize() int64 {
+	v := os.Getenv("MAX_UPLOAD_SIZE")
+	if v == "" {
+		return defaultMaxUploadSize
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return defaultMaxUploadSize
+	}
+	return n
+}
+
+// uploadedFile describes one object stored by uploadHandler.
+type uploadedFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	ETag        string `json:"etag"`
+	DownloadURL string `json:"download_url"`
+}
+
+type uploadResponse struct {
+	Files []uploadedFile `json:"files"`
+}
+
+// uploadHandler accepts a multipart/form-data POST containing one or more
+// "file" parts and stores each under the caller's namespace. A caller may
+// supply "key" as a form field to name the uploaded file explicitly; "key"
+// is rejected with 400 when the request carries more than one file. Without
+// "key", each file gets a collision-free name generated from random bytes
+// plus an extension (see randomObjectName).
+func uploadHandler(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize())
+	if err := r.ParseMultipartForm(maxUploadSize()); err != nil {
+		http.Error(w, fmt.Sprintf("Upload too large or malformed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fileHeaders := r.MultipartForm.File["file"]
+	if len(fileHeaders) == 0 {
+		http.Error(w, "Missing 'file' form part", http.StatusBadRequest)
+		return
+	}
+
+	explicitKey := r.FormValue("key")
+	if explicitKey != "" && len(fileHeaders) > 1 {
+		http.Error(w, "'key' cannot be used with multiple files", http.StatusBadRequest)
+		return
+	}
+
+	sse, err := resolveServerSideEncryption(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sseCfg.require && sse == nil {
+		http.Error(w, "Server-side encryption is required for uploads", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]uploadedFile, len(fileHeaders))
+	g, ctx := errgroup.WithContext(r.Context())
+
+	for i, fh := range fileHeaders {
+		i, fh := i, fh
+		g.Go(func() error {
+			file, err := fh.Open()
+			if err != nil {
+				return fmt.Errorf("opening %s: %w", fh.Filename, err)
+			}
+			defer file.Close()
+
+			sniff := make([]byte, 512)
+			n, err := file.Read(sniff)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("reading %s: %w", fh.Filename, err)
+			}
+			contentType := http.DetectContentType(sniff[:n])
+
+			objectName := explicitKey
+			if objectName == "" {
+				objectName, err = randomObjectName(fh.Filename, contentType)
+				if err != nil {
+					return err
+				}
+			}
+			key, err := scopedKey(claims, objectName)
+			if err != nil {
+				return err
+			}
+
+			body := io.MultiReader(bytes.NewReader(sniff[:n]), file)
+			info, err := minioClient.PutObject(ctx, bucketName, key, body, fh.Size, minio.PutObjectOptions{
+				ContentType:          contentType,
+				ServerSideEncryption: sse,
+			})
+			if err != nil {
+				return fmt.Errorf("uploading %s: %w", fh.Filename, err)
+			}
+
+			downloadURL, err := minioClient.PresignedGetObject(ctx, bucketName, key, presignExpiry, nil)
+			if err != nil {
+				return fmt.Errorf("presigning %s: %w", fh.Filename, err)
+			}
+
+			results[i] = uploadedFile{
+				Key:         objectName,
+				Size:        info.Size,
+				ETag:        info.ETag,
+				DownloadURL: downloadURL.String(),
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		http.Error(w, fmt.Sprintf("Upload failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploadResponse{Files: results})
+}
+
+// randomObjectName builds a collision-free object name from crypto/rand
+// hex bytes, preserving an extension from the original filename or, failing
+// that, one inferred from the sniffed content type.
+func randomObjectName(originalName, contentType string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating object name: %w", err)
+	}
+
+	ext := filepath.Ext(originalName)
+	if ext == "" {
+		if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return hex.EncodeToString(buf) + ext, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+// webhookQueueSize bounds the number of pending deliveries so a burst of
+// events (or a slow/dead endpoint) can't grow memory unboundedly; once full,
+// new deliveries for that event are dropped and logged.
+const webhookQueueSize = 1000
+
+// webhookMaxAttempts is the number of delivery attempts before a delivery is
+// given up on.
+const webhookMaxAttempts = 5
+
+// webhookInitialBackoff is the delay before the first retry; it doubles on
+// each subsequent attempt.
+const webhookInitialBackoff = time.Second
+
+// webhookWorkerCount bounds how many deliveries run concurrently, so one
+// slow or dead endpoint only ties up one worker's slot instead of blocking
+// delivery to every other hook.
+const webhookWorkerCount = 8
+
+// webhookHTTPTimeout bounds each single delivery attempt (every retry gets
+// its own timeout), so a non-responding endpoint can't occupy a worker
+// forever.
+const webhookHTTPTimeout = 10 * time.Second
+
+var webhookHTTPClient = &http.Client{Timeout: webhookHTTPTimeout}
+
+type webhookDelivery struct {
+	hook  Hook
+	event string
+	key   string
+	body  []byte
+}
+
+var webhookQueue chan webhookDelivery
+
+// startWebhookDispatcher launches the worker pool that drains webhookQueue
+// and the bucket-notification listener that feeds it. It runs for the
+// lifetime of the process.
+func startWebhookDispatcher(ctx context.Context) {
+	webhookQueue = make(chan webhookDelivery, webhookQueueSize)
+	for i := 0; i < webhookWorkerCount; i++ {
+		go runWebhookWorker(ctx)
+	}
+	go listenBucketNotifications(ctx)
+}
+
+func listenBucketNotifications(ctx context.Context) {
+	events := []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+	for {
+		notificationCh := minioClient.ListenBucketNotification(ctx, bucketName, "", "", events)
+		for info := range notificationCh {
+			if info.Err != nil {
+				log.Printf("Bucket notification error: %v", info.Err)
+				continue
+			}
+			dispatchNotification(info.Records)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			log.Println("Bucket notification stream closed, reconnecting in 2s...")
+			time.Sleep(2 * time.Second)
+		}
+	}
+}
+
+func dispatchNotification(records []notification.Event) {
+	list, err := hooks.List()
+	if err != nil {
+		log.Printf("Listing hooks for dispatch: %v", err)
+		return
+	}
+
+	for _, rec := range records {
+		key := rec.S3.Object.Key
+		event := rec.EventName
+		body, err := json.Marshal(rec)
+		if err != nil {
+			log.Printf("Marshaling notification for %s: %v", key, err)
+			continue
+		}
+
+		for _, h := range list {
+			if !h.Matches(key, event) {
+				continue
+			}
+			select {
+			case webhookQueue <- webhookDelivery{hook: h, event: event, key: key, body: body}:
+			default:
+				log.Printf("Webhook queue full, dropping delivery of %s to hook %s", event, h.ID)
+			}
+		}
+	}
+}
+
+func runWebhookWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-webhookQueue:
+			deliver(ctx, d)
+		}
+	}
+}
+
+func deliver(ctx context.Context, d webhookDelivery) {
+	backoff := webhookInitialBackoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		if err := send(ctx, d); err != nil {
+			log.Printf("Webhook delivery to %s failed (attempt %d/%d): %v", d.hook.URL, attempt, webhookMaxAttempts, err)
+			if attempt == webhookMaxAttempts {
+				return
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+			continue
+		}
+		return
+	}
+}
+
+func send(ctx context.Context, d webhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hook-Event", d.event)
+	if d.hook.Secret != "" {
+		req.Header.Set("X-Hook-Signature", signBody(d.hook.Secret, d.body))
+	}
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &webhookStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+func signBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookStatusError struct {
+	statusCode int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.statusCode, http.StatusText(e.statusCode))
+}